@@ -0,0 +1,13 @@
+// Command discardedmuts-multi runs the discardedmod analyzer through
+// multichecker, so it can be combined with other analysis.Analyzers in a
+// single binary instead of shelling out to each checker separately.
+package main
+
+import (
+	"github.com/daemon-developer/lint-discardedmuts/pkg/discardedmuts" // Update with actual import path
+	"golang.org/x/tools/go/analysis/multichecker"
+)
+
+func main() {
+	multichecker.Main(discardedmuts.DiscardedModificationAnalyzer)
+}
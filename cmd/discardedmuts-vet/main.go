@@ -0,0 +1,13 @@
+// Command discardedmuts-vet runs the discardedmod analyzer through
+// unitchecker, so it can be installed as a GOVETTOOL and driven by
+// `go vet ./...` on a per-compilation-unit basis.
+package main
+
+import (
+	"github.com/daemon-developer/lint-discardedmuts/pkg/discardedmuts" // Update with actual import path
+	"golang.org/x/tools/go/analysis/unitchecker"
+)
+
+func main() {
+	unitchecker.Main(discardedmuts.DiscardedModificationAnalyzer)
+}
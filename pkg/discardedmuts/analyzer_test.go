@@ -0,0 +1,13 @@
+package discardedmuts_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/daemon-developer/lint-discardedmuts/pkg/discardedmuts" // Update with actual import path
+)
+
+func TestDiscardedModificationAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), discardedmuts.DiscardedModificationAnalyzer, "a")
+}
@@ -0,0 +1,120 @@
+package discardedmuts
+
+import (
+	"fmt"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// mutatesPointerParam is an analysis.Fact recording which of a function's
+// pointer parameters (by index) it writes through, directly or by handing
+// them to another function that does. Case 3 uses it to stay quiet for
+// read-only callees such as fmt.Println(&x).
+type mutatesPointerParam struct {
+	Params []int
+}
+
+func (*mutatesPointerParam) AFact() {}
+
+func (f *mutatesPointerParam) String() string {
+	return fmt.Sprintf("mutatesPointerParam%v", f.Params)
+}
+
+// paramMutations computes and memoizes mutatesPointerParam facts for the
+// functions of the package under analysis, recursing into callees in the
+// same package and consulting imported facts for everything else.
+type paramMutations struct {
+	pass    *analysis.Pass
+	visited map[*ssa.Function]bool
+	result  map[*ssa.Function][]int
+}
+
+func newParamMutations(pass *analysis.Pass) *paramMutations {
+	return &paramMutations{
+		pass:    pass,
+		visited: make(map[*ssa.Function]bool),
+		result:  make(map[*ssa.Function][]int),
+	}
+}
+
+// paramIsMutated reports whether callee writes through its parameter at
+// index, directly or transitively.
+func (m *paramMutations) paramIsMutated(callee *ssa.Function, index int) bool {
+	if callee == nil {
+		return false
+	}
+	for _, idx := range m.mutatedParamsOf(callee) {
+		if idx == index {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *paramMutations) mutatedParamsOf(fn *ssa.Function) []int {
+	if fn.Pkg != nil && fn.Pkg.Pkg == m.pass.Pkg {
+		return m.mutatedParams(fn)
+	}
+	if fn.Object() == nil {
+		return nil
+	}
+	var fact mutatesPointerParam
+	if m.pass.ImportObjectFact(fn.Object(), &fact) {
+		return fact.Params
+	}
+	return nil
+}
+
+// mutatedParams computes, memoizes and (for named functions) exports the
+// set of pointer-parameter indices that fn writes through.
+func (m *paramMutations) mutatedParams(fn *ssa.Function) []int {
+	if indices, ok := m.result[fn]; ok {
+		return indices
+	}
+	if m.visited[fn] {
+		// A call cycle: assume no mutation here to break the recursion. Any
+		// direct write in the cycle is still found when that function's own
+		// turn comes up.
+		return nil
+	}
+	m.visited[fn] = true
+
+	var mutated []int
+	for i, p := range fn.Params {
+		if _, ok := p.Type().(*types.Pointer); ok && m.writesThroughParam(fn, p) {
+			mutated = append(mutated, i)
+		}
+	}
+
+	m.result[fn] = mutated
+	// Facts are only worth exporting when they say something other than the
+	// default "nothing mutated" a missing fact already implies (see printf,
+	// nilness): exporting one for every function bloats gob data across
+	// every package for no benefit.
+	if obj := fn.Object(); obj != nil && len(mutated) > 0 {
+		m.pass.ExportObjectFact(obj, &mutatesPointerParam{Params: mutated})
+	}
+	return mutated
+}
+
+func (m *paramMutations) writesThroughParam(fn *ssa.Function, p *ssa.Parameter) bool {
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			switch instr := instr.(type) {
+			case *ssa.Store:
+				if rootValue(instr.Addr) == p {
+					return true
+				}
+			case *ssa.Call:
+				for i, arg := range instr.Call.Args {
+					if arg == p && m.paramIsMutated(instr.Call.StaticCallee(), i) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
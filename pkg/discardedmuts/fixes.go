@@ -0,0 +1,165 @@
+package discardedmuts
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// fixCategory groups every SuggestedFix this analyzer emits so gopls/go vet
+// -fix can address them as a unit.
+const fixCategory = "discardedmod"
+
+// storeFixes builds the SuggestedFixes offered for a discarded store into
+// root (Case 1/2).
+func storeFixes(pass *analysis.Pass, idx *syntaxIndex, root deadRoot) []analysis.SuggestedFix {
+	if root.rangeStmt != nil {
+		return rangeValueFix(pass, root)
+	}
+	return valueParamFix(pass, idx, root)
+}
+
+// addrFixes builds the SuggestedFixes offered for taking the address of
+// root and passing it to a mutating callee (Case 3), or for the parameter
+// equivalent of the same mistake.
+func addrFixes(pass *analysis.Pass, idx *syntaxIndex, root deadRoot) []analysis.SuggestedFix {
+	if root.rangeStmt != nil {
+		return rangeAddrFix(pass, root)
+	}
+	return valueParamFix(pass, idx, root)
+}
+
+// valueParamFix rewrites a by-value parameter or receiver into a pointer:
+// the declaration gains a '*', and every read/write of it in the body is
+// dereferenced. Uses as a selector base (x.Field) are parenthesized so the
+// result is (*x).Field rather than the invalid *x.Field.
+func valueParamFix(pass *analysis.Pass, idx *syntaxIndex, root deadRoot) []analysis.SuggestedFix {
+	if root.object == nil {
+		return nil
+	}
+	decl, ok := idx.params[root.object]
+	if !ok {
+		return nil
+	}
+	if len(decl.field.Names) > 1 {
+		// "func f(a, b T)" shares one *ast.Field between a and b; inserting
+		// '*' before T would silently repoint the untouched sibling too.
+		// Splitting the group correctly needs more than a single insertion,
+		// so offer no fix rather than a wrong one.
+		return nil
+	}
+
+	edits := []analysis.TextEdit{{
+		Pos:     decl.field.Type.Pos(),
+		End:     decl.field.Type.Pos(),
+		NewText: []byte("*"),
+	}}
+	ast.Inspect(decl.body, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok && pass.TypesInfo.ObjectOf(id) == root.object {
+				edits = append(edits, analysis.TextEdit{
+					Pos:     id.Pos(),
+					End:     id.End(),
+					NewText: []byte("(*" + id.Name + ")"),
+				})
+				return false
+			}
+		}
+		if id, ok := n.(*ast.Ident); ok && pass.TypesInfo.ObjectOf(id) == root.object {
+			edits = append(edits, analysis.TextEdit{Pos: id.Pos(), End: id.Pos(), NewText: []byte("*")})
+		}
+		return true
+	})
+
+	return []analysis.SuggestedFix{{
+		Message:   fmt.Sprintf("change %s to a pointer", root.object.Name()),
+		TextEdits: edits,
+	}}
+}
+
+// rangeValueFix rewrites "for k, v := range s { v = ... }" into
+// "for k := range s { s[k] = ... }" (Case 2).
+func rangeValueFix(pass *analysis.Pass, root deadRoot) []analysis.SuggestedFix {
+	rs := root.rangeStmt
+	if rs == nil || root.object == nil {
+		return nil
+	}
+	keyName, keyEdit := rangeKey(rs)
+	sliceText := types.ExprString(rs.X)
+	replacement := fmt.Sprintf("%s[%s]", sliceText, keyName)
+
+	edits := rangeUsageEdits(pass, rs.Body, root.object, replacement, false)
+	if keyEdit != nil {
+		edits = append(edits, *keyEdit)
+	}
+	edits = append(edits, analysis.TextEdit{Pos: rs.Key.End(), End: rs.Value.End(), NewText: nil})
+
+	return []analysis.SuggestedFix{{
+		Message:   fmt.Sprintf("index %s directly instead of ranging over a copy", sliceText),
+		TextEdits: edits,
+	}}
+}
+
+// rangeAddrFix rewrites "for _, c := range s { bar(&c) }" into
+// "for i := range s { bar(&s[i]) }" (Case 3).
+func rangeAddrFix(pass *analysis.Pass, root deadRoot) []analysis.SuggestedFix {
+	rs := root.rangeStmt
+	if rs == nil || root.object == nil {
+		return nil
+	}
+	keyName, keyEdit := rangeKey(rs)
+	sliceText := types.ExprString(rs.X)
+	replacement := fmt.Sprintf("&%s[%s]", sliceText, keyName)
+
+	edits := rangeUsageEdits(pass, rs.Body, root.object, replacement, true)
+	if keyEdit != nil {
+		edits = append(edits, *keyEdit)
+	}
+	edits = append(edits, analysis.TextEdit{Pos: rs.Key.End(), End: rs.Value.End(), NewText: nil})
+
+	return []analysis.SuggestedFix{{
+		Message:   fmt.Sprintf("take the address of %s[%s] directly instead of a copy", sliceText, keyName),
+		TextEdits: edits,
+	}}
+}
+
+// rangeKey returns the name to use for the loop's key variable (its current
+// name if it already has one other than "_", or "i" otherwise) and, in the
+// latter case, the TextEdit that introduces it.
+func rangeKey(rs *ast.RangeStmt) (string, *analysis.TextEdit) {
+	if ident, ok := rs.Key.(*ast.Ident); ok && ident.Name != "_" {
+		return ident.Name, nil
+	}
+	return "i", &analysis.TextEdit{Pos: rs.Key.Pos(), End: rs.Key.End(), NewText: []byte("i")}
+}
+
+// rangeUsageEdits finds every use of valueObj within body and edits it to
+// replacement. When asAddr is true, "&v" usages are replaced whole, and any
+// other use of v gets replacement without its leading '&'; otherwise plain
+// uses of v are replaced.
+func rangeUsageEdits(pass *analysis.Pass, body ast.Node, valueObj types.Object, replacement string, asAddr bool) []analysis.TextEdit {
+	var edits []analysis.TextEdit
+	plain := replacement
+	if asAddr {
+		plain = strings.TrimPrefix(replacement, "&")
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		if asAddr {
+			if unary, ok := n.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+				if id, ok := unary.X.(*ast.Ident); ok && pass.TypesInfo.ObjectOf(id) == valueObj {
+					edits = append(edits, analysis.TextEdit{Pos: unary.Pos(), End: unary.End(), NewText: []byte(replacement)})
+					return false
+				}
+			}
+		}
+		if id, ok := n.(*ast.Ident); ok && pass.TypesInfo.ObjectOf(id) == valueObj {
+			edits = append(edits, analysis.TextEdit{Pos: id.Pos(), End: id.End(), NewText: []byte(plain)})
+		}
+		return true
+	})
+	return edits
+}
@@ -0,0 +1,77 @@
+package discardedmuts
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// syntaxIndex precomputes the syntax lookups detection and fix
+// construction need — parameter/receiver declarations (of both
+// *ast.FuncDecl and *ast.FuncLit, so closures and methods are covered) and
+// range statements — in a single inspector.Preorder pass over the package,
+// rather than the repeated whole-package ast.Inspect walks building a fix
+// used to require.
+type syntaxIndex struct {
+	params paramIndex
+	// rangeValues is keyed by the position of a RangeStmt's Value
+	// identifier, so addRangeValueRoots can match it back to the
+	// *ssa.Alloc the builder spills that loop variable to, when its
+	// address is taken.
+	rangeValues map[token.Pos]*ast.RangeStmt
+}
+
+// paramDecl is the syntax behind a parameter object: the function body
+// it's scoped to, and the *ast.Field that names it (shared by every name in
+// a "a, b T" group).
+type paramDecl struct {
+	body  *ast.BlockStmt
+	field *ast.Field
+}
+
+type paramIndex map[types.Object]paramDecl
+
+func buildSyntaxIndex(pass *analysis.Pass) *syntaxIndex {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	idx := &syntaxIndex{
+		params:      make(paramIndex),
+		rangeValues: make(map[token.Pos]*ast.RangeStmt),
+	}
+
+	nodeFilter := []ast.Node{
+		(*ast.FuncDecl)(nil),
+		(*ast.FuncLit)(nil),
+		(*ast.RangeStmt)(nil),
+	}
+	insp.Preorder(nodeFilter, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			idx.indexParams(pass, n.Recv, n.Body)
+			idx.indexParams(pass, n.Type.Params, n.Body)
+		case *ast.FuncLit:
+			idx.indexParams(pass, n.Type.Params, n.Body)
+		case *ast.RangeStmt:
+			if n.Value != nil {
+				idx.rangeValues[n.Value.Pos()] = n
+			}
+		}
+	})
+	return idx
+}
+
+func (idx *syntaxIndex) indexParams(pass *analysis.Pass, fields *ast.FieldList, body *ast.BlockStmt) {
+	if fields == nil || body == nil {
+		return
+	}
+	for _, field := range fields.List {
+		for _, name := range field.Names {
+			if obj := pass.TypesInfo.ObjectOf(name); obj != nil {
+				idx.params[obj] = paramDecl{body: body, field: field}
+			}
+		}
+	}
+}
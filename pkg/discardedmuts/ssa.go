@@ -0,0 +1,274 @@
+package discardedmuts
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// deadRoot describes an SSA value that can never be observed by the caller:
+// a non-pointer parameter, or the per-iteration value produced by ranging
+// over a slice/array by copy. label is the source-level name used in
+// diagnostics; storeNoun/addrNoun are the noun phrases used to describe it
+// when it's written to, or when its address is taken, respectively. object
+// and rangeStmt identify the declaring syntax, for building suggested
+// fixes; rangeStmt is nil for parameter roots.
+type deadRoot struct {
+	label     string
+	storeNoun string
+	addrNoun  string
+	object    types.Object
+	rangeStmt *ast.RangeStmt
+}
+
+// checkFunction looks for stores and address-taking instructions whose
+// ultimate target is a deadRoot, and reports them. buildssa.SSA.SrcFuncs
+// already flattens every closure (*ast.FuncLit) into the list run() iterates
+// over, so checkFunction must not also recurse into fn.AnonFuncs itself —
+// doing both double-reports (or worse) every diagnostic inside a closure.
+func checkFunction(pass *analysis.Pass, pm *paramMutations, idx *syntaxIndex, fn *ssa.Function) {
+	roots := deadRoots(pass, idx, fn)
+	if len(roots) > 0 {
+		for _, block := range fn.Blocks {
+			for _, instr := range block.Instrs {
+				checkInstr(pass, pm, idx, roots, instr)
+			}
+		}
+	}
+	checkElementCopies(pass, fn)
+}
+
+func checkInstr(pass *analysis.Pass, pm *paramMutations, idx *syntaxIndex, roots map[ssa.Value]deadRoot, instr ssa.Instruction) {
+	switch instr := instr.(type) {
+	case *ssa.Store:
+		if instr.Pos() == token.NoPos {
+			// The builder's own spill of a parameter into its address-taken
+			// Alloc ("*allocTs = ts") is itself a *ssa.Store whose Addr
+			// root-resolves to that same Alloc; it carries no source position
+			// because no source statement produced it. Without this guard
+			// every address-taken parameter gets a second, bogus diagnostic at
+			// an invalid position alongside the real one.
+			return
+		}
+		if root, ok := lookupRoot(roots, instr.Addr); ok {
+			if root.rangeStmt != nil && instr.Addr == rootValue(instr.Addr) && instr.Pos() == root.rangeStmt.Value.Pos() {
+				// The loop's own per-iteration copy into the range value's
+				// Alloc is positioned at the value identifier itself; it is
+				// not a mutation by the loop body.
+				return
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:            instr.Pos(),
+				Category:       fixCategory,
+				Message:        fmt.Sprintf("modification to %s %s will be discarded", root.storeNoun, memberExpr(root, instr.Addr)),
+				SuggestedFixes: storeFixes(pass, idx, root),
+			})
+		}
+	case *ssa.Call:
+		callee := instr.Call.StaticCallee()
+		for i, arg := range instr.Call.Args {
+			root, ok := lookupRoot(roots, arg)
+			if !ok {
+				continue
+			}
+			// A statically known callee that provably never writes through
+			// this parameter can't discard anything; stay quiet. An
+			// unresolved callee (interface method, func value) is reported
+			// conservatively, as before.
+			if callee != nil && !pm.paramIsMutated(callee, i) {
+				continue
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:            instr.Pos(),
+				Category:       fixCategory,
+				Message:        fmt.Sprintf("passing address of %s %s will modify a copy, not the original", root.addrNoun, memberExpr(root, arg)),
+				SuggestedFixes: addrFixes(pass, idx, root),
+			})
+		}
+	}
+}
+
+// lookupRoot walks v back through field/index addressing to find the
+// addressable value it was derived from, and reports whether that value is
+// a known deadRoot.
+func lookupRoot(roots map[ssa.Value]deadRoot, v ssa.Value) (deadRoot, bool) {
+	root, ok := roots[rootValue(v)]
+	return root, ok
+}
+
+// rootValue walks back through FieldAddr/IndexAddr chains to the
+// addressable instruction they were computed from: typically an *ssa.Alloc
+// or an *ssa.Parameter.
+func rootValue(v ssa.Value) ssa.Value {
+	for {
+		switch addr := v.(type) {
+		case *ssa.FieldAddr:
+			v = addr.X
+		case *ssa.IndexAddr:
+			v = addr.X
+		default:
+			return v
+		}
+	}
+}
+
+// memberExpr reconstructs a human-readable name for v relative to root,
+// e.g. "ts.Name" for a FieldAddr on parameter ts.
+func memberExpr(root deadRoot, v ssa.Value) string {
+	switch addr := v.(type) {
+	case *ssa.FieldAddr:
+		st := addr.X.Type().Underlying()
+		if ptr, ok := st.(*types.Pointer); ok {
+			st = ptr.Elem().Underlying()
+		}
+		if structType, ok := st.(*types.Struct); ok && addr.Field < structType.NumFields() {
+			return memberExpr(root, addr.X) + "." + structType.Field(addr.Field).Name()
+		}
+	case *ssa.IndexAddr:
+		return memberExpr(root, addr.X) + "[i]"
+	}
+	return root.label
+}
+
+// deadRoots computes the set of SSA values for fn whose mutation can never
+// be observed by the caller: non-pointer parameters and value receivers
+// (including the Alloc the builder spills them to when their address is
+// taken) and range-loop value variables over a slice or array.
+func deadRoots(pass *analysis.Pass, idx *syntaxIndex, fn *ssa.Function) map[ssa.Value]deadRoot {
+	roots := make(map[ssa.Value]deadRoot)
+	hasRecv := fn.Signature.Recv() != nil
+	for i, p := range fn.Params {
+		if isPointer(p.Type()) {
+			continue
+		}
+		if capturedByClosure(fn, p) {
+			continue
+		}
+		noun := "value parameter"
+		if hasRecv && i == 0 {
+			noun = "value receiver"
+		}
+		roots[p] = deadRoot{label: p.Name(), storeNoun: noun, addrNoun: noun, object: p.Object()}
+	}
+	addRangeValueRoots(pass, idx, fn, roots)
+	// A parameter or range value whose address is taken gets spilled by the
+	// builder into a stack slot; attribute FieldAddr/IndexAddr chains rooted
+	// at that slot back to the original name.
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			alloc, ok := instr.(*ssa.Alloc)
+			if !ok {
+				continue
+			}
+			if root, ok := allocRoot(fn, alloc, roots); ok {
+				roots[alloc] = root
+			}
+		}
+	}
+	return roots
+}
+
+// addRangeValueRoots registers the per-iteration value of a
+// "for _, v := range s" loop as a deadRoot when s is a slice or array, since
+// v is a copy of each element and mutating it never reaches s.
+//
+// go/ssa never emits *ssa.Range/*ssa.Next for a slice or array — that
+// lowering is reserved for maps and strings; ranging over a slice/array
+// instead compiles to an index counter and *ssa.IndexAddr, and v only gets
+// its own addressable *ssa.Alloc if something in the loop body takes its
+// address or assigns through it, which is exactly the case this analyzer
+// cares about. So find that Alloc directly: fn.addLocal stamps it with the
+// position of the identifier it was declared for, which for a range value
+// is rs.Value, the same convention shortVarDeclIndexExpr relies on for ":="
+// locals.
+func addRangeValueRoots(pass *analysis.Pass, idx *syntaxIndex, fn *ssa.Function, roots map[ssa.Value]deadRoot) {
+	for _, alloc := range fn.Locals {
+		rangeStmt, ok := idx.rangeValues[alloc.Pos()]
+		if !ok {
+			continue
+		}
+		xtyp := pass.TypesInfo.TypeOf(rangeStmt.X)
+		if xtyp == nil || !isSliceOrArray(xtyp) {
+			continue
+		}
+		valueIdent, ok := rangeStmt.Value.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		roots[alloc] = deadRoot{
+			label:     valueIdent.Name,
+			storeNoun: "element of slice",
+			addrNoun:  "slice element",
+			object:    pass.TypesInfo.ObjectOf(valueIdent),
+			rangeStmt: rangeStmt,
+		}
+	}
+}
+
+func isSliceOrArray(typ types.Type) bool {
+	switch typ.Underlying().(type) {
+	case *types.Slice, *types.Array:
+		return true
+	default:
+		return false
+	}
+}
+
+// allocRoot reports whether alloc is the stack slot the builder created to
+// hold the address-taken copy of a parameter already in roots, so that
+// FieldAddr/IndexAddr chains rooted at the Alloc are still attributed to the
+// original parameter name.
+func allocRoot(fn *ssa.Function, alloc *ssa.Alloc, roots map[ssa.Value]deadRoot) (deadRoot, bool) {
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			store, ok := instr.(*ssa.Store)
+			if !ok || store.Addr != alloc {
+				continue
+			}
+			if root, ok := roots[store.Val]; ok {
+				return root, true
+			}
+		}
+	}
+	return deadRoot{}, false
+}
+
+// capturedByClosure reports whether p's address is bound into a
+// *ssa.MakeClosure, in which case a pointer to it genuinely escapes to the
+// caller's heap and mutations through that pointer are observable.
+func capturedByClosure(fn *ssa.Function, p *ssa.Parameter) bool {
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			mc, ok := instr.(*ssa.MakeClosure)
+			if !ok {
+				continue
+			}
+			for _, binding := range mc.Bindings {
+				if binding == p {
+					return true
+				}
+				// A captured parameter is bound through the Alloc the builder
+				// spills it to, so match the Alloc that stores p.
+				alloc, ok := binding.(*ssa.Alloc)
+				if !ok {
+					continue
+				}
+				for _, ref := range *alloc.Referrers() {
+					if store, ok := ref.(*ssa.Store); ok && store.Addr == alloc && store.Val == p {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+func isPointer(typ types.Type) bool {
+	_, ok := typ.(*types.Pointer)
+	return ok
+}
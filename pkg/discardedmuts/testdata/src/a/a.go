@@ -33,7 +33,7 @@ func SetChecked(c *Collectable, value bool) {
 func modifyArraySlice() [3]int {
 	arr := [3]int{1, 2, 3}
 	for _, v := range arr {
-		modifyElement(&v, 0)
+		modifyElement(&v, 0) // want "passing address of slice element v will modify a copy, not the original"
 	}
 	return arr
 }
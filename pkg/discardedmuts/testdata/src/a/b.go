@@ -0,0 +1,22 @@
+package a
+
+// Counter exercises the value-receiver case: mutating through a receiver
+// that wasn't declared as a pointer is just as discarded as mutating a
+// by-value parameter.
+type Counter struct {
+	n int
+}
+
+func (c Counter) Increment() {
+	c.n = c.n + 1 // want "modification to value receiver c.n will be discarded"
+}
+
+func updateCollectable(byName map[string]Collectable, key string) {
+	c := byName[key]
+	c.checked = true // want `modification to c.checked will be discarded because c is a copy; assign it back, e.g. byName\[key\] = Collectable\{\.\.\.\}`
+}
+
+func updateSliceElement(collectables []Collectable, i int) {
+	c := collectables[i]
+	c.checked = true // want `modification to c.checked will be discarded because c is a copy; assign it back, e.g. &collectables\[i\]`
+}
@@ -0,0 +1,27 @@
+package a
+
+// closures exercises nested func literals. buildssa.SSA.SrcFuncs already
+// flattens every closure into the list the analyzer walks, so each one must
+// be checked exactly once no matter how deeply it's nested; regress this and
+// the diagnostic below gets reported more than once.
+func closures() {
+	outer := func() {
+		mutate := func(other TestStruct) {
+			other.Name = "discarded" // want "modification to value parameter other.Name will be discarded"
+		}
+		mutate(TestStruct{})
+	}
+	outer()
+}
+
+// closureOverRange exercises a range-loop value variable declared inside a
+// *ast.FuncLit rather than a *ast.FuncDecl, the case addRangeValueRoots
+// covers via syntaxIndex.rangeValues rather than fn.Locals positions alone.
+func closureOverRange(ts *TestStruct) {
+	process := func() {
+		for _, c := range ts.collectables {
+			c.checked = true // want "modification to element of slice c.checked will be discarded"
+		}
+	}
+	process()
+}
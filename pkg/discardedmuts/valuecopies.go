@@ -0,0 +1,167 @@
+package discardedmuts
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// elementCopy describes a local variable known to hold a copy of a map
+// value or slice/array element (Case 6b): "s := m[k]" or "s := slice[i]".
+// Field assignments through such a variable never reach the original
+// container, the same defect as Case 1, just reached by a different route.
+type elementCopy struct {
+	varName    string
+	suggestion string
+}
+
+// checkElementCopies reports field assignments through locals initialized
+// by copying a map value or a slice/array element.
+func checkElementCopies(pass *analysis.Pass, fn *ssa.Function) {
+	copies := elementCopies(pass, fn)
+	if len(copies) == 0 {
+		return
+	}
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			store, ok := instr.(*ssa.Store)
+			if !ok {
+				continue
+			}
+			fieldAddr, ok := store.Addr.(*ssa.FieldAddr)
+			if !ok {
+				continue
+			}
+			alloc, ok := fieldAddr.X.(*ssa.Alloc)
+			if !ok {
+				continue
+			}
+			cp, ok := copies[alloc]
+			if !ok {
+				continue
+			}
+			pass.Reportf(store.Pos(), "modification to %s.%s will be discarded because %s is a copy; assign it back, e.g. %s",
+				cp.varName, fieldName(alloc, fieldAddr), cp.varName, cp.suggestion)
+		}
+	}
+}
+
+// elemTypeName returns the name of the struct type alloc holds a copy of,
+// for use in a "container[key] = T{...}" suggestion. Qualified relative to
+// pass's own package so same-package types print bare, e.g. "Collectable"
+// rather than their full import path.
+func elemTypeName(pass *analysis.Pass, alloc *ssa.Alloc) string {
+	ptr, ok := alloc.Type().(*types.Pointer)
+	if !ok {
+		return ""
+	}
+	return types.TypeString(ptr.Elem(), types.RelativeTo(pass.Pkg))
+}
+
+func fieldName(alloc *ssa.Alloc, fieldAddr *ssa.FieldAddr) string {
+	ptr, ok := alloc.Type().(*types.Pointer)
+	if !ok {
+		return ""
+	}
+	st, ok := ptr.Elem().Underlying().(*types.Struct)
+	if !ok || fieldAddr.Field >= st.NumFields() {
+		return ""
+	}
+	return st.Field(fieldAddr.Field).Name()
+}
+
+// elementCopies finds Allocs in fn initialized by a short variable
+// declaration "s := m[k]" or "s := slice[i]", keyed by the Alloc so field
+// stores through it can be matched back to the originating container and
+// key/index expression.
+func elementCopies(pass *analysis.Pass, fn *ssa.Function) map[*ssa.Alloc]elementCopy {
+	copies := make(map[*ssa.Alloc]elementCopy)
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			store, ok := instr.(*ssa.Store)
+			if !ok {
+				continue
+			}
+			alloc, ok := store.Addr.(*ssa.Alloc)
+			if !ok || alloc.Comment == "" {
+				continue
+			}
+			if !copiesMapOrSliceElement(store.Val) {
+				continue
+			}
+			indexExpr := shortVarDeclIndexExpr(fn, alloc)
+			if indexExpr == nil {
+				continue
+			}
+			container := types.ExprString(indexExpr.X)
+			key := types.ExprString(indexExpr.Index)
+			var suggestion string
+			switch store.Val.(type) {
+			case *ssa.Lookup:
+				suggestion = fmt.Sprintf("%s[%s] = %s{...}", container, key, elemTypeName(pass, alloc))
+			default:
+				suggestion = fmt.Sprintf("&%s[%s]", container, key)
+			}
+			copies[alloc] = elementCopy{varName: alloc.Comment, suggestion: suggestion}
+		}
+	}
+	return copies
+}
+
+// copiesMapOrSliceElement reports whether v is a map lookup or a
+// dereferenced slice/array index, the two SSA shapes "m[k]" and "slice[i]"
+// take as an expression value.
+func copiesMapOrSliceElement(v ssa.Value) bool {
+	switch v := v.(type) {
+	case *ssa.Lookup:
+		_, isMap := v.X.Type().Underlying().(*types.Map)
+		return isMap && !v.CommaOk
+	case *ssa.UnOp:
+		if v.Op != token.MUL {
+			return false
+		}
+		addr, ok := v.X.(*ssa.IndexAddr)
+		if !ok {
+			return false
+		}
+		xt := addr.X.Type()
+		if ptr, ok := xt.Underlying().(*types.Pointer); ok {
+			// IndexAddr on an array indexes through a *array.
+			xt = ptr.Elem()
+		}
+		return isSliceOrArray(xt)
+	}
+	return false
+}
+
+// shortVarDeclIndexExpr finds the "name := container[key]" statement that
+// declared alloc, and returns its IndexExpr so the container/key can be
+// reconstructed for a diagnostic.
+func shortVarDeclIndexExpr(fn *ssa.Function, alloc *ssa.Alloc) *ast.IndexExpr {
+	syntax := fn.Syntax()
+	if syntax == nil {
+		return nil
+	}
+	var found *ast.IndexExpr
+	ast.Inspect(syntax, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || assign.Tok != token.DEFINE || len(assign.Lhs) != len(assign.Rhs) {
+			return true
+		}
+		for i, lhs := range assign.Lhs {
+			ident, ok := lhs.(*ast.Ident)
+			if !ok || ident.Name != alloc.Comment || ident.Pos() != alloc.Pos() {
+				continue
+			}
+			if idx, ok := assign.Rhs[i].(*ast.IndexExpr); ok {
+				found = idx
+			}
+		}
+		return true
+	})
+	return found
+}